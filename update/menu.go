@@ -3,75 +3,85 @@ package update
 import (
 	"fmt"
 	"io"
-	"text/tabwriter"
+	"sort"
+	"strings"
 
-	"github.com/pkg/term"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/weaveworks/flux"
 )
 
-// Escape sequences.
-const clearLines = "\033[%dA"
-const hideCursor = "\033[?25l"
-const showCursor = "\033[?25h"
-
-type WriteFlusher interface {
-	io.Writer
-	Flush() error
+type MenuItem struct {
+	id       flux.ResourceID
+	status   ControllerUpdateStatus
+	error    string
+	update   *ContainerUpdate
+	selected bool
 }
 
-type ClearableWriter struct {
-	wf    WriteFlusher
-	lines int
+func (i MenuItem) selectable() bool {
+	return i.update != nil
 }
 
-func NewClearableWriter(wf WriteFlusher) *ClearableWriter {
-	return &ClearableWriter{wf, 0}
+func (i MenuItem) updates() string {
+	if i.update != nil {
+		return fmt.Sprintf("%s: %s -> %s",
+			i.update.Container,
+			i.update.Current.String(),
+			i.update.Target.Tag)
+	}
+	return i.error
 }
 
-func (c *ClearableWriter) Write(p []byte) (n int, err error) {
-	for _, b := range p {
-		if b == '\n' {
-			c.lines++
-		}
+// matches reports whether the item should be shown under the given
+// filter substring, matching against controller, container and image.
+func (i MenuItem) matches(filter string) bool {
+	if filter == "" {
+		return true
 	}
-	return c.wf.Write(p)
+	haystack := strings.ToLower(i.id.String() + " " + i.updates())
+	return strings.Contains(haystack, strings.ToLower(filter))
 }
 
-func (c *ClearableWriter) Clear() {
-	fmt.Fprintf(c.wf, clearLines, c.lines)
-	c.lines = 0
-}
+type sortColumn int
 
-func (c *ClearableWriter) Flush() error {
-	return c.wf.Flush()
-}
+const (
+	sortByController sortColumn = iota
+	sortByStatus
+	sortByUpdates
+	numSortColumns
+)
 
-type MenuItem struct {
-	id       flux.ResourceID
-	status   ControllerUpdateStatus
-	error    string
-	update   *ContainerUpdate
-	selected bool
+func (s sortColumn) String() string {
+	switch s {
+	case sortByStatus:
+		return "status"
+	case sortByUpdates:
+		return "updates"
+	default:
+		return "controller"
+	}
 }
 
+// Menu is an interactive selector over a release Result set, built on
+// Bubbletea so that resizing, wrapped lines and result sets taller
+// than the window all render correctly.
 type Menu struct {
-	out        *ClearableWriter
-	results    Result
-	items      []MenuItem
-	cursor     int
-	selectable int
+	out     io.Writer
+	results Result
+	items   []MenuItem
+	verbose int
 }
 
-// PrintResults outputs a result set to the `io.Writer` provided, at
-// the given level of verbosity:
-//  - 2 = include skipped and ignored resources
-//  - 1 = include skipped resources, exclude ignored resources
-//  - 0 = exclude skipped and ignored resources
+// NewMenu prepares a Menu over the given Result set. Use Run to drive
+// it interactively, or RunNonInteractive to resolve a selection
+// without a terminal.
 func NewMenu(out io.Writer, results Result, verbosity int) *Menu {
 	m := &Menu{
-		out:     NewClearableWriter(tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)),
+		out:     out,
 		results: results,
+		verbose: verbosity,
 	}
 	m.fromResults(results, verbosity)
 	return m
@@ -100,6 +110,9 @@ func (m *Menu) fromResults(results Result, verbosity int) {
 			})
 		}
 		for _, upd := range result.PerContainer {
+			if len(upd.Patch) == 0 {
+				upd.Patch = imagePatch(upd)
+			}
 			m.AddItem(MenuItem{
 				id:     resourceID,
 				status: result.Status,
@@ -109,144 +122,330 @@ func (m *Menu) fromResults(results Result, verbosity int) {
 	}
 }
 
+// imagePatch synthesizes a minimal unified diff of the image change a
+// ContainerUpdate represents, for display in the menu's detail pane
+// when the release planner hasn't already rendered the full manifest
+// patch.
+func imagePatch(upd ContainerUpdate) []byte {
+	path := upd.ManifestPath
+	if path == "" {
+		path = fmt.Sprintf("%s manifest", upd.Container)
+	}
+	return []byte(fmt.Sprintf(
+		"--- a/%s\n+++ b/%s\n@@ -1 +1 @@\n-        image: %s\n+        image: %s\n",
+		path, path, upd.Current.String(), upd.Target.String()))
+}
+
 func (m *Menu) AddItem(mi MenuItem) {
 	m.items = append(m.items, mi)
-	if mi.selectable() {
-		m.selectable++
+}
+
+// Run drives the interactive Bubbletea menu and returns the set of
+// updates the user selected. If stdin/stdout is not a terminal, it
+// aborts rather than prompting; callers without a tty (CI, GitOps
+// automation) should use RunNonInteractive with a Selector instead.
+func (m *Menu) Run() (selected []ContainerUpdate, aborted bool) {
+	if !IsTerminal() {
+		fmt.Fprintln(m.out, "Not running in a terminal: use --select-from for non-interactive selection.")
+		return nil, true
 	}
+
+	model := newMenuModel(m.items)
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	final, err := p.Run()
+	if err != nil {
+		fmt.Fprintln(m.out, "Aborted.")
+		return nil, true
+	}
+
+	mm := final.(menuModel)
+	if mm.aborted {
+		return nil, true
+	}
+	for _, item := range mm.items {
+		if item.selected {
+			selected = append(selected, *item.update)
+		}
+	}
+	return selected, false
+}
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true).Underline(true)
+	cursorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("212"))
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	filterStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+// menuModel is the Bubbletea model backing Menu.Run: a scrollable,
+// filterable, sortable list of MenuItems with checkbox-style
+// selection and mouse support.
+type menuModel struct {
+	items      []MenuItem
+	visible    []int // indices into items that pass the current filter, in sort order
+	cursor     int   // index into visible
+	offset     int   // first visible row shown (for pagination/scrolling)
+	height     int   // terminal rows available to the list
+	width      int
+	filtering  bool
+	filter     string
+	sortColumn sortColumn
+	aborted    bool
+	expanded   bool // show the detail pane for the highlighted row
 }
 
-func (m *Menu) toggleCursor() {
-	m.items[m.cursor].selected = !m.items[m.cursor].selected
-	m.Render()
+func newMenuModel(items []MenuItem) menuModel {
+	m := menuModel{items: items, height: 20}
+	m.applyFilter()
+	return m
 }
 
-func (m *Menu) cursorDown() {
-	m.cursor = (m.cursor + 1) % m.selectable
-	m.Render()
+func (m menuModel) Init() tea.Cmd {
+	return nil
 }
 
-func (m *Menu) cursorUp() {
-	m.cursor = (m.cursor + m.selectable - 1) % m.selectable
-	m.Render()
+func (m *menuModel) applyFilter() {
+	m.visible = m.visible[:0]
+	for i, item := range m.items {
+		if item.matches(m.filter) {
+			m.visible = append(m.visible, i)
+		}
+	}
+	sort.SliceStable(m.visible, func(a, b int) bool {
+		ia, ib := m.items[m.visible[a]], m.items[m.visible[b]]
+		switch m.sortColumn {
+		case sortByStatus:
+			return ia.status < ib.status
+		case sortByUpdates:
+			return ia.updates() < ib.updates()
+		default:
+			return ia.id.String() < ib.id.String()
+		}
+	})
+	if m.cursor >= len(m.visible) {
+		m.cursor = len(m.visible) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
 }
 
-func (m *Menu) Run() (selected []ContainerUpdate, aborted bool) {
-	defer fmt.Printf(showCursor)
+func (m *menuModel) toggle(i int) {
+	if i < 0 || i >= len(m.visible) {
+		return
+	}
+	idx := m.visible[i]
+	if m.items[idx].selectable() {
+		m.items[idx].selected = !m.items[idx].selected
+	}
+}
 
-	m.Render()
-	for {
-		ascii, keyCode, err := getChar()
+func (m *menuModel) selectAll(selected bool) {
+	for _, idx := range m.visible {
+		if m.items[idx].selectable() {
+			m.items[idx].selected = selected
+		}
+	}
+}
 
-		if (ascii == 3 || ascii == 27) || err != nil {
-			fmt.Fprintln(m.out, "Aborted.")
-			return selected, true
+func (m *menuModel) invertAll() {
+	for _, idx := range m.visible {
+		if m.items[idx].selectable() {
+			m.items[idx].selected = !m.items[idx].selected
 		}
+	}
+}
+
+func (m *menuModel) ensureVisible() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+// rowAt maps a mouse click's screen row back to an index into
+// m.visible. The cursor row may be followed by a multi-line detail
+// pane when m.expanded is set, which pushes every row below it down
+// by the detail's line count; a click that lands inside the detail
+// pane itself doesn't correspond to any row.
+func (m menuModel) rowAt(y int) (index int, ok bool) {
+	row := y - 1 // header occupies row 0
+	if row < 0 {
+		return 0, false
+	}
+
+	cursorRow := m.cursor - m.offset
+	detailLines := 0
+	if m.expanded && len(m.visible) > 0 {
+		detailLines = strings.Count(renderDetail(m.items[m.visible[m.cursor]]), "\n")
+	}
+
+	switch {
+	case row < cursorRow:
+		index = m.offset + row
+	case row == cursorRow:
+		index = m.cursor
+	case row <= cursorRow+detailLines:
+		return 0, false // click landed inside the detail pane
+	default:
+		index = m.offset + row - detailLines
+	}
+
+	if index < 0 || index >= len(m.visible) {
+		return 0, false
+	}
+	return index, true
+}
 
-		if ascii == ' ' {
-			m.toggleCursor()
-		} else if ascii == 13 {
-			for _, item := range m.items {
-				if item.selected {
-					selected = append(selected, *item.update)
+func (m menuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height - 3 // header + filter/status lines
+		if m.height < 1 {
+			m.height = 1
+		}
+		m.ensureVisible()
+		return m, nil
+
+	case tea.MouseMsg:
+		if msg.Type == tea.MouseLeft {
+			if i, ok := m.rowAt(msg.Y); ok {
+				m.cursor = i
+				m.toggle(i)
+			}
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.filtering {
+			switch msg.Type {
+			case tea.KeyEsc:
+				m.filtering = false
+				m.filter = ""
+				m.applyFilter()
+			case tea.KeyEnter:
+				m.filtering = false
+			case tea.KeyBackspace:
+				if len(m.filter) > 0 {
+					m.filter = m.filter[:len(m.filter)-1]
+					m.applyFilter()
 				}
+			default:
+				m.filter += msg.String()
+				m.applyFilter()
 			}
-			fmt.Println()
-			return
+			return m, nil
 		}
 
-		if keyCode == 40 {
-			m.cursorDown()
-		} else if keyCode == 38 {
-			m.cursorUp()
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.aborted = true
+			return m, tea.Quit
+		case "enter":
+			return m, tea.Quit
+		case " ":
+			m.toggle(m.cursor)
+		case "down", "j":
+			if m.cursor < len(m.visible)-1 {
+				m.cursor++
+			}
+			m.ensureVisible()
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			m.ensureVisible()
+		case "pgdown":
+			m.cursor += m.height
+			if m.cursor > len(m.visible)-1 {
+				m.cursor = len(m.visible) - 1
+			}
+			m.ensureVisible()
+		case "pgup":
+			m.cursor -= m.height
+			if m.cursor < 0 {
+				m.cursor = 0
+			}
+			m.ensureVisible()
+		case "/":
+			m.filtering = true
+		case "a":
+			m.selectAll(true)
+		case "n":
+			m.selectAll(false)
+		case "i":
+			m.invertAll()
+		case "o":
+			m.sortColumn = (m.sortColumn + 1) % numSortColumns
+			m.applyFilter()
+		case "d", "right":
+			m.expanded = !m.expanded
 		}
+		return m, nil
 	}
-	return
+	return m, nil
 }
 
-func (m *Menu) Render() {
-	m.out.Clear()
+func (m menuModel) View() string {
+	var b strings.Builder
+	fmt.Fprintln(&b, headerStyle.Render("   CONTROLLER                                    STATUS     UPDATES"))
+
+	end := m.offset + m.height
+	if end > len(m.visible) {
+		end = len(m.visible)
+	}
+	for row := m.offset; row < end; row++ {
+		item := m.items[m.visible[row]]
+		m.renderItem(&b, item, row == m.cursor)
+		if row == m.cursor && m.expanded {
+			b.WriteString(renderDetail(item))
+		}
+	}
+
+	if m.filtering {
+		fmt.Fprintf(&b, "/%s\n", m.filter)
+	} else {
+		fmt.Fprint(&b, filterStyle.Render(fmt.Sprintf(
+			"%d/%d selected · sort:%s · [space] toggle [a]ll [n]one [i]nvert [/] filter [d/→] details [enter] confirm [esc] quit\n",
+			m.countSelected(), len(m.visible), m.sortColumn)))
+	}
+	return b.String()
+}
 
-	fmt.Fprintln(m.out, "   CONTROLLER \tSTATUS \tUPDATES")
-	i := 0
+func (m menuModel) countSelected() int {
+	n := 0
 	for _, item := range m.items {
-		m.renderItem(&item, i == m.cursor)
-		if item.selectable() {
-			i++
+		if item.selected {
+			n++
 		}
 	}
-	fmt.Printf(hideCursor)
-	m.out.Flush()
+	return n
 }
 
-func (m *Menu) renderItem(item *MenuItem, cursor bool) {
+func (m menuModel) renderItem(b *strings.Builder, item MenuItem, cursor bool) {
 	curs := " "
 	if cursor {
 		curs = ">"
 	}
-	fmt.Fprintf(m.out, "%s%s %s\t%s\t%s\n", curs, item.checkbox(), item.id.String(), item.status, item.updates())
-}
-
-func (i MenuItem) selectable() bool {
-	return i.update != nil
+	line := fmt.Sprintf("%s%s %-45s %-10s %s\n", curs, checkbox(item), item.id.String(), item.status, item.updates())
+	if cursor {
+		line = cursorStyle.Render(line)
+	} else if item.selected {
+		line = selectedStyle.Render(line)
+	}
+	b.WriteString(line)
 }
 
-func (i MenuItem) checkbox() string {
+func checkbox(i MenuItem) string {
 	switch {
 	case !i.selectable():
 		return " "
 	case i.selected:
-		return "\u25c9"
+		return "◉"
 	default:
-		return "\u25ef"
-	}
-}
-
-func (i MenuItem) updates() string {
-	if i.update != nil {
-		return fmt.Sprintf("%s: %s -> %s",
-			i.update.Container,
-			i.update.Current.String(),
-			i.update.Target.Tag)
-	}
-	return i.error
-}
-
-// See https://github.com/paulrademacher/climenu/blob/master/getchar.go
-func getChar() (ascii int, keyCode int, err error) {
-	t, _ := term.Open("/dev/tty")
-	term.RawMode(t)
-	bytes := make([]byte, 3)
-
-	var numRead int
-	numRead, err = t.Read(bytes)
-	if err != nil {
-		return
-	}
-	if numRead == 3 && bytes[0] == 27 && bytes[1] == 91 {
-		// Three-character control sequence, beginning with "ESC-[".
-
-		// Since there are no ASCII codes for arrow keys, we use
-		// Javascript key codes.
-		if bytes[2] == 65 {
-			// Up
-			keyCode = 38
-		} else if bytes[2] == 66 {
-			// Down
-			keyCode = 40
-		} else if bytes[2] == 67 {
-			// Right
-			keyCode = 39
-		} else if bytes[2] == 68 {
-			// Left
-			keyCode = 37
-		}
-	} else if numRead == 1 {
-		ascii = int(bytes[0])
-	} else {
-		// Two characters read??
+		return "◯"
 	}
-	t.Restore()
-	t.Close()
-	return
 }
@@ -0,0 +1,39 @@
+package update
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// detailStyle indents the expanded detail pane under its row so it
+// reads as a child of the highlighted item rather than a new row.
+var detailStyle = lipgloss.NewStyle().PaddingLeft(4).Faint(true)
+
+// renderDetail renders the expanded view for a highlighted row: the
+// full current and target image refs (including digest, via
+// image.Ref.String()), the manifest file the update would touch, and
+// a unified diff of the YAML change `fluxctl` would commit. Patch is
+// always populated by the time it reaches here (see imagePatch in
+// menu.go); ManifestPath is only set when the release planner that
+// built the Result had a source location to offer, so that line is
+// omitted otherwise.
+func renderDetail(item MenuItem) string {
+	if !item.selectable() {
+		return ""
+	}
+	upd := item.update
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "current:  %s\n", upd.Current.String())
+	fmt.Fprintf(&b, "target:   %s\n", upd.Target.String())
+	if upd.ManifestPath != "" {
+		fmt.Fprintf(&b, "manifest: %s\n", upd.ManifestPath)
+	}
+	if len(upd.Patch) > 0 {
+		b.WriteString(strings.TrimRight(string(upd.Patch), "\n"))
+		b.WriteString("\n")
+	}
+	return detailStyle.Render(b.String()) + "\n"
+}
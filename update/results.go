@@ -0,0 +1,63 @@
+package update
+
+import (
+	"sort"
+
+	"github.com/weaveworks/flux"
+	"github.com/weaveworks/flux/image"
+)
+
+// ControllerUpdateStatus describes the outcome of considering a
+// controller for release: whether it was updated, skipped (e.g.
+// locked), ignored (e.g. excluded by filter), or failed.
+type ControllerUpdateStatus string
+
+const (
+	ReleaseStatusSuccess ControllerUpdateStatus = "success"
+	ReleaseStatusFailed  ControllerUpdateStatus = "failed"
+	ReleaseStatusSkipped ControllerUpdateStatus = "skipped"
+	ReleaseStatusIgnored ControllerUpdateStatus = "ignored"
+)
+
+// ContainerUpdate describes a single container image change within a
+// controller.
+type ContainerUpdate struct {
+	Container string
+	Current   image.Ref
+	Target    image.Ref
+
+	// ManifestPath is the path, relative to the repository root, of
+	// the manifest file this update would change. It is set by
+	// whatever constructs the Result (the release planner) when a
+	// source location is available; it may be empty.
+	ManifestPath string
+	// Patch is a unified diff of the YAML change this update would
+	// commit. fromResults fills this in from Current/Target when the
+	// release planner hasn't already supplied one, so the menu's
+	// detail pane always has something to show.
+	Patch []byte
+}
+
+// ControllerResult is the per-controller outcome of a release: its
+// status, an error if it has one, and the per-container updates that
+// make it up.
+type ControllerResult struct {
+	Status       ControllerUpdateStatus
+	Error        string
+	PerContainer []ContainerUpdate
+}
+
+// Result is the outcome of a release calculation, keyed by the
+// resource ID of the controller considered.
+type Result map[flux.ResourceID]ControllerResult
+
+// ServiceIDs returns the controllers in Result, sorted for stable
+// display order.
+func (r Result) ServiceIDs() []string {
+	ids := make([]string, 0, len(r))
+	for id := range r {
+		ids = append(ids, id.String())
+	}
+	sort.Strings(ids)
+	return ids
+}
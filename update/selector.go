@@ -0,0 +1,150 @@
+package update
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
+
+// SelectorEntry identifies one or more ContainerUpdates to select,
+// either by exact controller/container/target or by glob pattern
+// (e.g. `"controller": "default:deployment/*"`). An empty field
+// matches anything. Patterns are matched with path.Match, which,
+// unlike a shell glob, does not let "*" cross a "/" — a pattern has to
+// include the literal segment it's glob-ing within, as in the example
+// above, rather than matching "default:deployment/foo" with a bare
+// "*".
+type SelectorEntry struct {
+	Controller string `json:"controller"`
+	Container  string `json:"container"`
+	Target     string `json:"target"`
+}
+
+// fieldMatch pairs a pattern from a SelectorEntry with the value of
+// the item field it constrains, so that two fields sharing the same
+// pattern string are still checked independently.
+type fieldMatch struct {
+	pattern string
+	value   string
+}
+
+func (e SelectorEntry) matches(item MenuItem) (bool, error) {
+	if !item.selectable() {
+		return false, nil
+	}
+	fields := [...]fieldMatch{
+		{e.Controller, item.id.String()},
+		{e.Container, item.update.Container},
+		{e.Target, item.update.Target.Tag},
+	}
+	for _, f := range fields {
+		if f.pattern == "" {
+			continue
+		}
+		// path.Match treats "/" as a separator like a shell glob, so
+		// a bare "*" pattern for Controller won't match IDs of the
+		// form "default:deployment/foo" across the slash; patterns
+		// need an explicit segment, e.g. "default:deployment/*".
+		ok, err := path.Match(f.pattern, f.value)
+		if err != nil {
+			return false, fmt.Errorf("invalid selector pattern %q: %s", f.pattern, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Selector is a list of SelectorEntry to resolve against a Menu's
+// items; an item is selected if it matches any entry.
+type Selector struct {
+	Entries []SelectorEntry
+}
+
+// UnmarshalJSON accepts a bare JSON array of SelectorEntry, which is
+// the documented `--select-from` stdin format (a plain list of
+// `{controller, container, target}` triples, with no enclosing
+// object).
+func (s *Selector) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &s.Entries)
+}
+
+// MarshalJSON mirrors UnmarshalJSON, emitting the bare array rather
+// than wrapping it in an object.
+func (s Selector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Entries)
+}
+
+// ReadSelector decodes a Selector from JSON, as read from a
+// `--select-from` file or stdin.
+func ReadSelector(r io.Reader) (Selector, error) {
+	var s Selector
+	err := json.NewDecoder(r).Decode(&s)
+	return s, err
+}
+
+// RunNonInteractive resolves a selection without prompting on the
+// terminal, so that release selection can be scripted from CI or
+// GitOps automation where `--interactive` has no tty to run against.
+func (m *Menu) RunNonInteractive(selection Selector) (selected []ContainerUpdate, err error) {
+	for _, item := range m.items {
+		for _, entry := range selection.Entries {
+			ok, err := entry.matches(item)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				selected = append(selected, *item.update)
+				break
+			}
+		}
+	}
+	return selected, nil
+}
+
+// EncodeContainerUpdates writes updates as JSON, for `--output=json`.
+func EncodeContainerUpdates(w io.Writer, updates []ContainerUpdate) error {
+	return json.NewEncoder(w).Encode(updates)
+}
+
+// ResolveSelection is the library half of `fluxctl release
+// --select-from=<file|->`: selectFrom is a filename, or "-" to read
+// the selector from in (typically os.Stdin) instead.
+//
+// NOTE: wiring the --select-from and --output flags onto the `release`
+// cobra command in cmd/fluxctl is a follow-up — that package isn't
+// part of this change. ResolveSelection/WriteSelection are what it
+// will call once it exists.
+func (m *Menu) ResolveSelection(selectFrom string, in io.Reader) ([]ContainerUpdate, error) {
+	r := in
+	if selectFrom != "-" {
+		f, err := os.Open(selectFrom)
+		if err != nil {
+			return nil, fmt.Errorf("opening --select-from file: %s", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	selector, err := ReadSelector(r)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --select-from selector: %s", err)
+	}
+	return m.RunNonInteractive(selector)
+}
+
+// WriteSelection writes a resolved selection to w, as JSON when
+// output is "json" (for `fluxctl release --output=json`) or as the
+// same human-readable `container: current -> target` summary the
+// interactive menu uses otherwise.
+func WriteSelection(w io.Writer, updates []ContainerUpdate, output string) error {
+	if output == "json" {
+		return EncodeContainerUpdates(w, updates)
+	}
+	for _, u := range updates {
+		fmt.Fprintf(w, "%s: %s -> %s\n", u.Container, u.Current.String(), u.Target.Tag)
+	}
+	return nil
+}
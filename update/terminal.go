@@ -0,0 +1,15 @@
+package update
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// IsTerminal reports whether both stdin and stdout are attached to a
+// terminal. The interactive menu can only run when this is true;
+// go-isatty gives a consistent answer on Windows consoles as well as
+// Unix ttys, unlike checking for the presence of `/dev/tty`.
+func IsTerminal() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+}